@@ -0,0 +1,125 @@
+// Package cache provides aur.Cache implementations for Client's response
+// cache: an in-memory LRU bounded by entry count and total bytes, and a
+// filesystem-backed cache for persisting entries across process restarts.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/mikkeloscar/aur"
+)
+
+// defaultMaxEntries and defaultMaxBytes bound a new LRU when NewLRU isn't
+// given WithMaxEntries/WithMaxBytes.
+const (
+	defaultMaxEntries = 1000
+	defaultMaxBytes   = 16 << 20 // 16 MiB
+)
+
+// LRU is an in-memory aur.Cache bounded by both entry count and total
+// cached body size, evicting the least recently used entry once either
+// limit is exceeded.
+type LRU struct {
+	maxEntries int
+	maxBytes   int
+
+	mu        sync.Mutex
+	ll        *list.List
+	items     map[string]*list.Element
+	totalSize int
+}
+
+type lruEntry struct {
+	key  string
+	resp *aur.CachedResponse
+}
+
+// LRUOption allows setting custom parameters during construction.
+type LRUOption func(*LRU)
+
+// WithMaxEntries overrides the default maximum number of cached entries.
+func WithMaxEntries(n int) LRUOption {
+	return func(l *LRU) {
+		l.maxEntries = n
+	}
+}
+
+// WithMaxBytes overrides the default maximum total size, in bytes, of
+// cached response bodies.
+func WithMaxBytes(n int) LRUOption {
+	return func(l *LRU) {
+		l.maxBytes = n
+	}
+}
+
+// NewLRU creates a new LRU cache.
+func NewLRU(opts ...LRUOption) *LRU {
+	l := &LRU{
+		maxEntries: defaultMaxEntries,
+		maxBytes:   defaultMaxBytes,
+		ll:         list.New(),
+		items:      map[string]*list.Element{},
+	}
+
+	for _, o := range opts {
+		o(l)
+	}
+
+	return l
+}
+
+// Get implements aur.Cache.
+func (l *LRU) Get(key string) (*aur.CachedResponse, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.ll.MoveToFront(elem)
+
+	return elem.Value.(*lruEntry).resp, true
+}
+
+// Set implements aur.Cache.
+func (l *LRU) Set(key string, resp *aur.CachedResponse, _ time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.totalSize -= len(elem.Value.(*lruEntry).resp.Body)
+		elem.Value.(*lruEntry).resp = resp
+		l.totalSize += len(resp.Body)
+		l.ll.MoveToFront(elem)
+		l.evict()
+
+		return
+	}
+
+	elem := l.ll.PushFront(&lruEntry{key: key, resp: resp})
+	l.items[key] = elem
+	l.totalSize += len(resp.Body)
+
+	l.evict()
+}
+
+// evict removes least recently used entries until both bounds are
+// satisfied. l.mu must be held.
+func (l *LRU) evict() {
+	for (l.maxEntries > 0 && l.ll.Len() > l.maxEntries) || (l.maxBytes > 0 && l.totalSize > l.maxBytes) {
+		elem := l.ll.Back()
+		if elem == nil {
+			return
+		}
+
+		l.ll.Remove(elem)
+
+		entry := elem.Value.(*lruEntry)
+		delete(l.items, entry.key)
+		l.totalSize -= len(entry.resp.Body)
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mikkeloscar/aur"
+)
+
+func TestLRU_GetSet(t *testing.T) {
+	l := NewLRU()
+
+	_, hit := l.Get("a")
+	assert.False(t, hit)
+
+	l.Set("a", &aur.CachedResponse{Body: []byte("one")}, 0)
+
+	got, hit := l.Get("a")
+	assert.True(t, hit)
+	assert.Equal(t, []byte("one"), got.Body)
+}
+
+func TestLRU_EvictsByMaxEntries(t *testing.T) {
+	l := NewLRU(WithMaxEntries(2))
+
+	l.Set("a", &aur.CachedResponse{Body: []byte("a")}, 0)
+	l.Set("b", &aur.CachedResponse{Body: []byte("b")}, 0)
+	l.Set("c", &aur.CachedResponse{Body: []byte("c")}, 0)
+
+	_, hit := l.Get("a")
+	assert.False(t, hit, "oldest entry should have been evicted")
+
+	_, hit = l.Get("b")
+	assert.True(t, hit)
+
+	_, hit = l.Get("c")
+	assert.True(t, hit)
+}
+
+func TestLRU_EvictsByMaxBytes(t *testing.T) {
+	l := NewLRU(WithMaxBytes(5))
+
+	l.Set("a", &aur.CachedResponse{Body: []byte("abc")}, 0)
+	l.Set("b", &aur.CachedResponse{Body: []byte("abc")}, 0)
+
+	_, hit := l.Get("a")
+	assert.False(t, hit, "total size exceeding maxBytes should evict the oldest entry")
+
+	_, hit = l.Get("b")
+	assert.True(t, hit)
+}
+
+func TestLRU_GetRefreshesRecency(t *testing.T) {
+	l := NewLRU(WithMaxEntries(2))
+
+	l.Set("a", &aur.CachedResponse{Body: []byte("a")}, 0)
+	l.Set("b", &aur.CachedResponse{Body: []byte("b")}, 0)
+
+	l.Get("a")
+
+	l.Set("c", &aur.CachedResponse{Body: []byte("c")}, 0)
+
+	_, hit := l.Get("b")
+	assert.False(t, hit, "b should have been evicted as the least recently used entry")
+
+	_, hit = l.Get("a")
+	assert.True(t, hit)
+}
+
+func TestFS_GetSet(t *testing.T) {
+	c, err := NewFS(WithDir(t.TempDir()))
+	assert.NoError(t, err)
+
+	_, hit := c.Get("missing")
+	assert.False(t, hit)
+
+	c.Set("key", &aur.CachedResponse{Body: []byte("payload"), ETag: `"abc"`}, 0)
+
+	got, hit := c.Get("key")
+	assert.True(t, hit)
+	assert.Equal(t, []byte("payload"), got.Body)
+	assert.Equal(t, `"abc"`, got.ETag)
+}
+
+func TestNewFS_DefaultsDir(t *testing.T) {
+	c, err := NewFS()
+	assert.NoError(t, err)
+	assert.Contains(t, c.Dir, defaultCacheDirName)
+}
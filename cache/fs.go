@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mikkeloscar/aur"
+)
+
+// defaultCacheDirName is the directory created under the resolved cache
+// root, unless overridden with WithDir.
+const defaultCacheDirName = "go-aur"
+
+// FS is a filesystem-backed aur.Cache that persists entries as individual
+// JSON files under Dir, so a cache populated by one process survives
+// across restarts.
+type FS struct {
+	// Dir entries are stored in. Created on first Set if it doesn't exist.
+	Dir string
+}
+
+// FSOption allows setting custom parameters during construction.
+type FSOption func(*FS) error
+
+// WithDir overrides the directory entries are stored in, which otherwise
+// defaults to "go-aur" under $XDG_CACHE_HOME, or os.UserCacheDir() if
+// that's unset.
+func WithDir(dir string) FSOption {
+	return func(c *FS) error {
+		c.Dir = dir
+
+		return nil
+	}
+}
+
+// NewFS creates a new filesystem-backed cache.
+func NewFS(opts ...FSOption) (*FS, error) {
+	c := &FS{}
+
+	for _, o := range opts {
+		if err := o(c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Dir == "" {
+		root, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Dir = filepath.Join(root, defaultCacheDirName)
+	}
+
+	return c, nil
+}
+
+// fsEntry is the on-disk representation of a cached response.
+type fsEntry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Get implements aur.Cache.
+func (c *FS) Get(key string) (*aur.CachedResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry fsEntry
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	return &aur.CachedResponse{
+		Body:         entry.Body,
+		ETag:         entry.ETag,
+		LastModified: entry.LastModified,
+		ExpiresAt:    entry.ExpiresAt,
+	}, true
+}
+
+// Set implements aur.Cache.
+func (c *FS) Set(key string, resp *aur.CachedResponse, _ time.Duration) {
+	data, err := json.Marshal(fsEntry{
+		Body:         resp.Body,
+		ETag:         resp.ETag,
+		LastModified: resp.LastModified,
+		ExpiresAt:    resp.ExpiresAt,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return
+	}
+
+	_ = os.WriteFile(c.path(key), data, 0o644)
+}
+
+// path returns the file a key is stored under, named by its SHA-256 hash
+// so arbitrary cache keys are safe path components.
+func (c *FS) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
@@ -1,12 +1,19 @@
 package aur
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 type PayloadError struct {
@@ -15,11 +22,54 @@ type PayloadError struct {
 }
 
 func (r *PayloadError) Error() string {
-	return fmt.Sprintf("status %d: err %s", r.StatusCode, r.ErrorField)
+	return fmt.Sprintf("status %d: %s", r.StatusCode, r.ErrorField)
 }
 
 const _defaultURL = "https://aur.archlinux.org/rpc.php?"
 
+// defaultFailoverCooldown is how long a mirror is pinned to the back of the
+// rotation after a failed request, unless overridden with WithFailoverCooldown.
+const defaultFailoverCooldown = time.Minute
+
+const (
+	// defaultMaxArgsPerRequest is the largest number of arg[] entries
+	// packed into a single Info request before Client starts a new chunk,
+	// unless overridden with WithMaxArgsPerRequest.
+	defaultMaxArgsPerRequest = 150
+
+	// maxInfoURLLength bounds the encoded length of an Info chunk so it
+	// stays well under the AUR RPC's ~4 KiB URL limit regardless of
+	// WithMaxArgsPerRequest.
+	maxInfoURLLength = 4000
+
+	// defaultInfoConcurrency is how many Info chunks are dispatched at
+	// once, unless overridden with WithInfoConcurrency.
+	defaultInfoConcurrency = 4
+)
+
+// defaultCacheTTL is how long a cached response is served without
+// revalidation, unless overridden with WithCacheTTL.
+const defaultCacheTTL = 5 * time.Minute
+
+// Cache is implemented by pluggable response caches for Client, keyed by a
+// string derived from the request's type, by, and sorted arg[] values so
+// Search and Info responses never collide.
+type Cache interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, resp *CachedResponse, ttl time.Duration)
+}
+
+// CachedResponse is the unit stored and retrieved from a Cache. Entries at
+// or past ExpiresAt are revalidated with If-None-Match/If-Modified-Since,
+// built from ETag/LastModified when present, so a 304 can be served
+// straight from Body without re-downloading it.
+type CachedResponse struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	ExpiresAt    time.Time
+}
+
 // The interface specification for the client above.
 type ClientInterface interface {
 	// Search queries the AUR DB with an optional By filter.
@@ -28,11 +78,17 @@ type ClientInterface interface {
 
 	// Info gives detailed information on existing package.
 	Info(ctx context.Context, pkgs []string, reqEditors ...RequestEditorFn) ([]Pkg, error)
+
+	// Suggest returns package name completions for prefix. When base is
+	// true it completes package base names instead.
+	Suggest(ctx context.Context, prefix string, base bool, reqEditors ...RequestEditorFn) ([]string, error)
 }
 
 // Client for AUR searching and querying.
 type Client struct {
-	baseURL string
+	// BaseURL of the endpoint used when no mirrors are configured via
+	// WithMirrors.
+	BaseURL string
 
 	// Doer for performing requests, typically a *http.Client with any
 	// customized settings, such as certificate chains.
@@ -41,6 +97,88 @@ type Client struct {
 	// A list of callbacks for modifying requests which are generated before sending over
 	// the network.
 	RequestEditors []RequestEditorFn
+
+	// maxRetries caps the number of mirrors tried per call. 0 means try
+	// every configured endpoint once.
+	maxRetries int
+
+	// retryBackoff, if set, is called before every retry attempt (not the
+	// first) with the attempt number starting at 1.
+	retryBackoff func(attempt int) time.Duration
+
+	// failoverCooldown is how long a failed endpoint is pinned to the back
+	// of the rotation before being retried.
+	failoverCooldown time.Duration
+
+	// maxArgsPerRequest caps how many arg[] entries Info packs into a
+	// single request before splitting into another chunk.
+	maxArgsPerRequest int
+
+	// infoConcurrency caps how many Info chunks are in flight at once.
+	infoConcurrency int
+
+	// cache, when set via WithCache, serves repeat requests without
+	// round-tripping to an AUR mirror.
+	cache Cache
+
+	// cacheTTL is how long a cached response is served without
+	// revalidation.
+	cacheTTL time.Duration
+
+	// responseObservers are notified, in order, after every HTTP
+	// round-trip, including failed mirror-retry attempts.
+	responseObservers []ResponseObserverFn
+
+	mu        sync.Mutex
+	endpoints []*endpointState
+}
+
+// endpointState tracks the health of a single AUR RPC mirror.
+type endpointState struct {
+	baseURL        string
+	unhealthyUntil time.Time
+}
+
+// ClusterError is returned by Client.get when every configured mirror failed
+// to serve a request. It holds the last error seen per mirror base URL so
+// callers can inspect what went wrong on each host.
+type ClusterError struct {
+	Errors map[string]error
+}
+
+func (e *ClusterError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("all AUR mirrors failed:")
+
+	for baseURL, err := range e.Errors {
+		fmt.Fprintf(&sb, " %s: %s;", baseURL, err)
+	}
+
+	return sb.String()
+}
+
+// MultiError is returned by Client.Info when one or more of its chunked
+// sub-requests failed. Errors preserves the order the failing chunks were
+// dispatched in.
+type MultiError struct {
+	Errors []error
+}
+
+func (e *MultiError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%d of the chunked Info requests failed:", len(e.Errors))
+
+	for _, err := range e.Errors {
+		fmt.Fprintf(&sb, " %s;", err)
+	}
+
+	return sb.String()
+}
+
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
 }
 
 // ClientOption allows setting custom parameters during construction.
@@ -56,11 +194,19 @@ type HTTPRequestDoer interface {
 // RequestEditorFn  is the function signature for the RequestEditor callback function.
 type RequestEditorFn func(ctx context.Context, req *http.Request) error
 
+// ResponseObserverFn is the function signature for the ResponseObserver
+// callback function. It is the response-side analog of RequestEditorFn:
+// resp is nil and err is non-nil when the round-trip itself failed (e.g. a
+// network error), as opposed to an AUR-level error status. Observers must
+// not read or close resp.Body, which is still needed for parsing.
+type ResponseObserverFn func(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error)
+
 func NewClient(opts ...ClientOption) (*Client, error) {
 	client := Client{
-		baseURL:        "",
-		HTTPClient:     nil,
-		RequestEditors: []RequestEditorFn{},
+		BaseURL:          "",
+		HTTPClient:       nil,
+		RequestEditors:   []RequestEditorFn{},
+		failoverCooldown: defaultFailoverCooldown,
 	}
 
 	// mutate client and add all optional params
@@ -76,18 +222,35 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	}
 
 	// set default baseURL if not present or valid
-	if client.baseURL == "" {
-		client.baseURL = _defaultURL
-	}
+	client.BaseURL = normalizeBaseURL(client.BaseURL)
 
-	// ensure the server URL always has a trailing slash
-	if !strings.HasSuffix(client.baseURL, "/") {
-		client.baseURL += "/"
+	// fall back to a single-endpoint cluster built from BaseURL if
+	// WithMirrors was not used.
+	if len(client.endpoints) == 0 {
+		client.endpoints = []*endpointState{{baseURL: client.BaseURL}}
 	}
 
 	return &client, nil
 }
 
+// normalizeBaseURL ensures base points at the rpc.php endpoint, appending it
+// when the caller only supplied a host.
+func normalizeBaseURL(base string) string {
+	if base == "" {
+		base = _defaultURL
+	}
+
+	if !strings.HasSuffix(base, "/") && !strings.HasSuffix(base, "?") {
+		base += "/"
+	}
+
+	if !strings.HasSuffix(base, "rpc.php?") {
+		base += "rpc.php?"
+	}
+
+	return base
+}
+
 // WithHTTPClient allows overriding the default Doer, which is
 // automatically created using http.Client. This is useful for tests.
 func WithHTTPClient(doer HTTPRequestDoer) ClientOption {
@@ -98,6 +261,113 @@ func WithHTTPClient(doer HTTPRequestDoer) ClientOption {
 	}
 }
 
+// WithBaseURL overrides the default AUR RPC base URL. It is ignored if
+// WithMirrors is also used.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		c.BaseURL = baseURL
+
+		return nil
+	}
+}
+
+// WithMirrors configures an ordered list of AUR RPC base URLs (e.g.
+// "aur.archlinux.org", a Tor mirror, or a local caching proxy) that Client
+// fails over between. The first reachable mirror is tried first; mirrors
+// that error are pinned to the back of the rotation for the failover
+// cooldown (see WithFailoverCooldown) and successful mirrors are promoted
+// to the head.
+func WithMirrors(mirrors []string) ClientOption {
+	return func(c *Client) error {
+		endpoints := make([]*endpointState, 0, len(mirrors))
+
+		for _, m := range mirrors {
+			endpoints = append(endpoints, &endpointState{baseURL: normalizeBaseURL(m)})
+		}
+
+		c.endpoints = endpoints
+
+		return nil
+	}
+}
+
+// WithFailoverCooldown overrides how long a failed mirror is pinned to the
+// back of the rotation before being retried. Defaults to one minute.
+func WithFailoverCooldown(d time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.failoverCooldown = d
+
+		return nil
+	}
+}
+
+// WithMaxRetries caps the number of mirrors tried per call. The default, 0,
+// tries every configured mirror once.
+func WithMaxRetries(n int) ClientOption {
+	return func(c *Client) error {
+		c.maxRetries = n
+
+		return nil
+	}
+}
+
+// WithRetryBackoff registers a function called before every retry attempt
+// (not the first one) to compute how long to sleep beforehand, given the
+// attempt number starting at 1. Useful for jittered backoff between mirror
+// attempts.
+func WithRetryBackoff(fn func(attempt int) time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.retryBackoff = fn
+
+		return nil
+	}
+}
+
+// WithMaxArgsPerRequest caps how many arg[] entries Client.Info packs into
+// a single request before splitting the remainder into another chunk.
+// Defaults to 150, which keeps well clear of the AUR RPC's "Too many
+// package results." rejection.
+func WithMaxArgsPerRequest(n int) ClientOption {
+	return func(c *Client) error {
+		c.maxArgsPerRequest = n
+
+		return nil
+	}
+}
+
+// WithInfoConcurrency caps how many chunked Info sub-requests are in
+// flight at once. Defaults to 4.
+func WithInfoConcurrency(n int) ClientOption {
+	return func(c *Client) error {
+		c.infoConcurrency = n
+
+		return nil
+	}
+}
+
+// WithCache plugs a response cache into Client. Identical URL+query
+// combinations are served from the cache within its TTL; on a miss, the
+// request is revalidated with If-None-Match/If-Modified-Since derived
+// from any previously cached ETag/Last-Modified, so a 304 is promoted
+// into a cache hit instead of being re-downloaded.
+func WithCache(cache Cache) ClientOption {
+	return func(c *Client) error {
+		c.cache = cache
+
+		return nil
+	}
+}
+
+// WithCacheTTL overrides how long a cached response is served without
+// revalidation. Defaults to 5 minutes.
+func WithCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) error {
+		c.cacheTTL = ttl
+
+		return nil
+	}
+}
+
 // WithRequestEditorFn allows setting up a callback function, which will be
 // called right before sending the request. This can be used to mutate the request.
 func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
@@ -108,6 +378,25 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 	}
 }
 
+// WithResponseObserverFn registers a callback invoked after every HTTP
+// round-trip Client makes, including ones repeated during mirror failover,
+// before the response body is parsed. Multiple observers can be
+// registered; each is called in the order it was added.
+func WithResponseObserverFn(fn ResponseObserverFn) ClientOption {
+	return func(c *Client) error {
+		c.responseObservers = append(c.responseObservers, fn)
+
+		return nil
+	}
+}
+
+// observeResponse notifies every registered response observer.
+func (c *Client) observeResponse(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+	for _, o := range c.responseObservers {
+		o(ctx, req, resp, latency, err)
+	}
+}
+
 func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
 	for _, r := range c.RequestEditors {
 		if err := r(ctx, req); err != nil {
@@ -124,10 +413,10 @@ func (c *Client) applyEditors(ctx context.Context, req *http.Request, additional
 	return nil
 }
 
-func newAURRPCRequest(baseURL string, values url.Values) (*http.Request, error) {
+func newAURRPCRequest(ctx context.Context, baseURL string, values url.Values) (*http.Request, error) {
 	values.Set("v", "5")
 
-	req, err := http.NewRequest("GET", baseURL+values.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+values.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -158,6 +447,25 @@ func parseRPCResponse(resp *http.Response) ([]Pkg, error) {
 	return result.Results, nil
 }
 
+// parseSuggestResponse parses the response to a type=suggest or
+// type=suggest-pkgbase request, which is a bare JSON array of strings
+// rather than the envelope used by parseRPCResponse.
+func parseSuggestResponse(resp *http.Response) ([]string, error) {
+	defer resp.Body.Close()
+
+	if err := getErrorByStatusCode(resp.StatusCode); err != nil {
+		return nil, err
+	}
+
+	var result []string
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("response decoding failed: %w", err)
+	}
+
+	return result, nil
+}
+
 // Search queries the AUR DB with an optional By field.
 // Use By.None for default query param (name-desc)
 func (c *Client) Search(ctx context.Context, query string, by By, reqEditors ...RequestEditorFn) ([]Pkg, error) {
@@ -172,33 +480,433 @@ func (c *Client) Search(ctx context.Context, query string, by By, reqEditors ...
 	return c.get(ctx, v, reqEditors)
 }
 
-// Info shows info for one or multiple packages.
+// Info shows info for one or multiple packages. pkgs is automatically
+// split into chunks that respect WithMaxArgsPerRequest and the AUR RPC's
+// URL length limit, dispatched concurrently (bounded by
+// WithInfoConcurrency), and merged back into a single, input-ordered,
+// Name-deduplicated slice.
 func (c *Client) Info(ctx context.Context, pkgs []string, reqEditors ...RequestEditorFn) ([]Pkg, error) {
+	chunks := chunkInfoArgs(pkgs, c.effectiveMaxArgsPerRequest(), maxInfoURLLength)
+
+	chunkResults := make([][]Pkg, len(chunks))
+	chunkErrors := make([]error, len(chunks))
+
+	sem := make(chan struct{}, c.effectiveInfoConcurrency())
+
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+
+		go func(i int, chunk []string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			v := url.Values{}
+			v.Set("type", "info")
+
+			for _, arg := range chunk {
+				v.Add("arg[]", arg)
+			}
+
+			chunkResults[i], chunkErrors[i] = c.get(ctx, v, reqEditors)
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	multiErr := &MultiError{}
+	seen := make(map[string]struct{}, len(pkgs))
+	merged := make([]Pkg, 0, len(pkgs))
+
+	for i, err := range chunkErrors {
+		if err != nil {
+			multiErr.Errors = append(multiErr.Errors, err)
+
+			continue
+		}
+
+		for _, pkg := range chunkResults[i] {
+			if _, ok := seen[pkg.Name]; ok {
+				continue
+			}
+
+			seen[pkg.Name] = struct{}{}
+			merged = append(merged, pkg)
+		}
+	}
+
+	// A single chunk has nothing to accumulate, so the raw error is
+	// returned instead of a MultiError.
+	if len(multiErr.Errors) == 1 {
+		return nil, multiErr.Errors[0]
+	}
+
+	if len(multiErr.Errors) > 0 {
+		return nil, multiErr
+	}
+
+	return merged, nil
+}
+
+// Suggest returns package name completions for prefix, suitable for
+// powering tab-completion in downstream tools without a full Search
+// round-trip. When base is true it queries package base completions
+// instead.
+func (c *Client) Suggest(ctx context.Context, prefix string, base bool, reqEditors ...RequestEditorFn) ([]string, error) {
 	v := url.Values{}
-	v.Set("type", "info")
 
-	for _, arg := range pkgs {
-		v.Add("arg[]", arg)
+	if base {
+		v.Set("type", "suggest-pkgbase")
+	} else {
+		v.Set("type", "suggest")
 	}
 
-	return c.get(ctx, v, reqEditors)
+	v.Set("arg", prefix)
+
+	return c.getSuggest(ctx, v, reqEditors)
+}
+
+// chunkInfoArgs splits pkgs into chunks of at most maxArgs entries each,
+// further splitting early if the next entry would push the chunk's
+// encoded arg[] length past maxURLLen.
+func chunkInfoArgs(pkgs []string, maxArgs, maxURLLen int) [][]string {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	var chunks [][]string
+
+	current := make([]string, 0, maxArgs)
+	currentLen := 0
+
+	for _, pkg := range pkgs {
+		argLen := len("arg[]=") + len(url.QueryEscape(pkg)) + 1
+
+		if len(current) > 0 && (len(current) >= maxArgs || currentLen+argLen > maxURLLen) {
+			chunks = append(chunks, current)
+			current = make([]string, 0, maxArgs)
+			currentLen = 0
+		}
+
+		current = append(current, pkg)
+		currentLen += argLen
+	}
+
+	chunks = append(chunks, current)
+
+	return chunks
+}
+
+func (c *Client) effectiveMaxArgsPerRequest() int {
+	if c.maxArgsPerRequest > 0 {
+		return c.maxArgsPerRequest
+	}
+
+	return defaultMaxArgsPerRequest
+}
+
+func (c *Client) effectiveInfoConcurrency() int {
+	if c.infoConcurrency > 0 {
+		return c.infoConcurrency
+	}
+
+	return defaultInfoConcurrency
 }
 
 func (c *Client) get(ctx context.Context, values url.Values, reqEditors []RequestEditorFn) ([]Pkg, error) {
-	req, err := newAURRPCRequest(c.baseURL, values)
-	if err != nil {
-		return nil, err
+	return doWithFailover(c, ctx, func(ctx context.Context, baseURL string) ([]Pkg, error) {
+		req, err := newAURRPCRequest(ctx, baseURL, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, cacheHit, err := c.doCachedRequest(req)
+
+		if !cacheHit {
+			c.observeResponse(ctx, req, resp, time.Since(start), err)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		return parseRPCResponse(resp)
+	})
+}
+
+func (c *Client) getSuggest(ctx context.Context, values url.Values, reqEditors []RequestEditorFn) ([]string, error) {
+	return doWithFailover(c, ctx, func(ctx context.Context, baseURL string) ([]string, error) {
+		req, err := newAURRPCRequest(ctx, baseURL, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		resp, cacheHit, err := c.doCachedRequest(req)
+
+		if !cacheHit {
+			c.observeResponse(ctx, req, resp, time.Since(start), err)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		return parseSuggestResponse(resp)
+	})
+}
+
+// doCachedRequest performs req, transparently serving and populating
+// c.cache if one is configured. A cache hit within TTL short-circuits the
+// request entirely, reported via the cacheHit return so callers can
+// exclude it from round-trip observations; a stale entry is revalidated
+// with If-None-Match/If-Modified-Since and, on a 304, served from the
+// cached body instead of being re-downloaded.
+func (c *Client) doCachedRequest(req *http.Request) (resp *http.Response, cacheHit bool, err error) {
+	if c.cache == nil {
+		resp, err = c.HTTPClient.Do(req)
+
+		return resp, false, err
 	}
 
-	req = req.WithContext(ctx)
-	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
-		return nil, err
+	key := cacheKey(req.URL.Query())
+
+	cached, hit := c.cache.Get(key)
+	if hit && time.Now().Before(cached.ExpiresAt) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(cached.Body)),
+		}, true, nil
+	}
+
+	if hit {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err = c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, false, err
+	}
+
+	if hit && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		cached.ExpiresAt = time.Now().Add(c.effectiveCacheTTL())
+		c.cache.Set(key, cached, c.effectiveCacheTTL())
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     resp.Header,
+			Body:       io.NopCloser(bytes.NewReader(cached.Body)),
+		}, false, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		c.cache.Set(key, &CachedResponse{
+			Body:         body,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			ExpiresAt:    time.Now().Add(c.effectiveCacheTTL()),
+		}, c.effectiveCacheTTL())
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return resp, false, nil
+}
+
+// cacheKey derives a cache key from a request's type, by, and arg[]
+// values so Search and Info responses, which use the same parameters
+// differently, never collide.
+func cacheKey(values url.Values) string {
+	args := append([]string(nil), values["arg[]"]...)
+	sort.Strings(args)
+
+	var sb strings.Builder
+
+	sb.WriteString(values.Get("type"))
+	sb.WriteByte('|')
+	sb.WriteString(values.Get("by"))
+	sb.WriteByte('|')
+	sb.WriteString(values.Get("arg"))
+	sb.WriteByte('|')
+	sb.WriteString(strings.Join(args, ","))
+
+	return sb.String()
+}
+
+func (c *Client) effectiveCacheTTL() time.Duration {
+	if c.cacheTTL > 0 {
+		return c.cacheTTL
 	}
 
-	return parseRPCResponse(resp)
+	return defaultCacheTTL
+}
+
+// doWithFailover walks c's mirror rotation, calling fn with each candidate
+// base URL until one succeeds, a non-retryable error is returned, or the
+// retry budget is exhausted.
+func doWithFailover[T any](
+	c *Client,
+	ctx context.Context,
+	fn func(ctx context.Context, baseURL string) (T, error),
+) (T, error) {
+	var zero T
+
+	c.mu.Lock()
+	if len(c.endpoints) == 0 {
+		c.endpoints = []*endpointState{{baseURL: c.BaseURL}}
+	}
+	c.mu.Unlock()
+
+	maxRetries := c.maxRetries
+	if maxRetries <= 0 {
+		maxRetries = c.endpointCount()
+	}
+
+	clusterErr := &ClusterError{Errors: map[string]error{}}
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 && c.retryBackoff != nil {
+			if d := c.retryBackoff(attempt - 1); d > 0 {
+				select {
+				case <-time.After(d):
+				case <-ctx.Done():
+					return zero, ctx.Err()
+				}
+			}
+		}
+
+		ep := c.pickEndpoint()
+
+		result, err := fn(ctx, ep.baseURL)
+		if err == nil {
+			c.reportSuccess(ep)
+
+			return result, nil
+		}
+
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return zero, err
+		}
+
+		clusterErr.Errors[ep.baseURL] = err
+
+		if !isRetryableErr(err) {
+			return zero, err
+		}
+
+		c.reportFailure(ep)
+	}
+
+	// With a single configured endpoint there's nothing for a ClusterError
+	// to add, so surface the underlying error directly.
+	if len(clusterErr.Errors) == 1 {
+		for _, err := range clusterErr.Errors {
+			return zero, err
+		}
+	}
+
+	return zero, clusterErr
+}
+
+// isRetryableErr reports whether a failed attempt should move on to the
+// next mirror: a network error (that isn't context cancellation/deadline,
+// already filtered out by the caller) or a 5xx mapped to
+// ErrServiceUnavailable.
+func isRetryableErr(err error) bool {
+	if errors.Is(err, ErrServiceUnavailable) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+func (c *Client) endpointCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.endpoints)
+}
+
+// pickEndpoint returns the first endpoint that isn't cooling down, or the
+// head of the rotation if every endpoint is currently unhealthy.
+func (c *Client) pickEndpoint() *endpointState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for _, ep := range c.endpoints {
+		if ep.unhealthyUntil.Before(now) {
+			return ep
+		}
+	}
+
+	return c.endpoints[0]
+}
+
+func (c *Client) reportSuccess(ep *endpointState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ep.unhealthyUntil = time.Time{}
+	c.moveToFrontLocked(ep)
+}
+
+func (c *Client) reportFailure(ep *endpointState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ep.unhealthyUntil = time.Now().Add(c.failoverCooldown)
+	c.moveToBackLocked(ep)
+}
+
+func (c *Client) moveToFrontLocked(ep *endpointState) {
+	for i, e := range c.endpoints {
+		if e == ep {
+			c.endpoints = append(c.endpoints[:i:i], c.endpoints[i+1:]...)
+			c.endpoints = append([]*endpointState{ep}, c.endpoints...)
+
+			return
+		}
+	}
+}
+
+func (c *Client) moveToBackLocked(ep *endpointState) {
+	for i, e := range c.endpoints {
+		if e == ep {
+			c.endpoints = append(c.endpoints[:i:i], c.endpoints[i+1:]...)
+			c.endpoints = append(c.endpoints, ep)
+
+			return
+		}
+	}
 }
@@ -3,10 +3,15 @@ package aur
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -390,3 +395,497 @@ func TestClient_InfoError(t *testing.T) {
 	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg%5B%5D=test&type=info&v=5",
 		requestMade.URL.String())
 }
+
+func TestNewClient_WithMirrors(t *testing.T) {
+	c, err := NewClient(WithMirrors([]string{"aur.archlinux.org", "aur-dev.archlinux.org"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpointState{
+		{baseURL: "aur.archlinux.org/rpc.php?"},
+		{baseURL: "aur-dev.archlinux.org/rpc.php?"},
+	}, c.endpoints)
+}
+
+func TestClient_FailoverToNextMirror(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(
+		WithHTTPClient(testClient),
+		WithMirrors([]string{"https://mirror-a.example/rpc.php?", "https://mirror-b.example/rpc.php?"}),
+	)
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Host == "mirror-a.example"
+	})).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	testClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.Host == "mirror-b.example"
+	})).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(validPayload))}, nil)
+
+	got, err := c.Info(context.Background(), []string{"test"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, validPayloadItems, got)
+	testClient.AssertNumberOfCalls(t, "Do", 2)
+
+	// the previously failing mirror was pinned to the back, so the
+	// promoted mirror-b is now tried first.
+	assert.Equal(t, "https://mirror-b.example/rpc.php?", c.endpoints[0].baseURL)
+}
+
+func TestClient_AllMirrorsFail(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(
+		WithHTTPClient(testClient),
+		WithMirrors([]string{"https://mirror-a.example/rpc.php?", "https://mirror-b.example/rpc.php?"}),
+	)
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	_, err = c.Info(context.Background(), []string{"test"})
+
+	var clusterErr *ClusterError
+	assert.ErrorAs(t, err, &clusterErr)
+	assert.Len(t, clusterErr.Errors, 2)
+
+	testClient.AssertNumberOfCalls(t, "Do", 2)
+}
+
+func TestClient_ContextCanceledStopsRetries(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(
+		WithHTTPClient(testClient),
+		WithMirrors([]string{"https://mirror-a.example/rpc.php?", "https://mirror-b.example/rpc.php?"}),
+	)
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return((*http.Response)(nil), context.Canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.Info(ctx, []string{"test"})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	testClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestClient_MaxRetries(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(
+		WithHTTPClient(testClient),
+		WithMirrors([]string{"https://mirror-a.example/rpc.php?", "https://mirror-b.example/rpc.php?"}),
+		WithMaxRetries(1),
+	)
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	_, err = c.Info(context.Background(), []string{"test"})
+
+	// A single attempt has nothing to accumulate, so the raw mirror error
+	// is returned instead of a ClusterError.
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+
+	testClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestClient_RetryBackoff(t *testing.T) {
+	testClient := new(MockedClient)
+
+	var sleptAttempts []int
+
+	c, err := NewClient(
+		WithHTTPClient(testClient),
+		WithMirrors([]string{"https://mirror-a.example/rpc.php?", "https://mirror-b.example/rpc.php?"}),
+		WithRetryBackoff(func(attempt int) time.Duration {
+			sleptAttempts = append(sleptAttempts, attempt)
+
+			return 0
+		}),
+	)
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	_, err = c.Info(context.Background(), []string{"test"})
+	assert.Error(t, err)
+
+	assert.Equal(t, []int{1}, sleptAttempts)
+}
+
+func Test_isRetryableErr(t *testing.T) {
+	assert.True(t, isRetryableErr(ErrServiceUnavailable))
+	assert.True(t, isRetryableErr(fmt.Errorf("wrapped: %w", ErrServiceUnavailable)))
+	assert.False(t, isRetryableErr(errors.New("some application error")))
+}
+
+func Test_chunkInfoArgs(t *testing.T) {
+	pkgs := []string{"a", "b", "c", "d", "e"}
+
+	got := chunkInfoArgs(pkgs, 2, 4000)
+
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, got)
+}
+
+func Test_chunkInfoArgs_respectsURLLength(t *testing.T) {
+	pkgs := []string{"aaaa", "bbbb", "cccc"}
+
+	// each entry encodes to "arg[]=xxxx " (11 bytes), so a 20 byte budget
+	// only fits one entry per chunk.
+	got := chunkInfoArgs(pkgs, 150, 20)
+
+	assert.Equal(t, [][]string{{"aaaa"}, {"bbbb"}, {"cccc"}}, got)
+}
+
+// doerFunc adapts a plain function to HTTPRequestDoer, handing each
+// concurrent call its own *http.Response so chunked requests don't race
+// over a shared response body.
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_InfoChunksLargeRequests(t *testing.T) {
+	var calls int32
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(noMatchPayload)),
+		}, nil
+	})
+
+	c, err := NewClient(WithHTTPClient(doer), WithMaxArgsPerRequest(2))
+	assert.NoError(t, err)
+
+	pkgs := []string{"p1", "p2", "p3", "p4", "p5"}
+
+	_, err = c.Info(context.Background(), pkgs)
+
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls)) // ceil(5/2)
+}
+
+func TestClient_InfoMergesOrderedAndDeduped(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(WithHTTPClient(testClient), WithMaxArgsPerRequest(2))
+	assert.NoError(t, err)
+
+	chunkPayload := func(names ...string) string {
+		results := make([]string, len(names))
+		for i, n := range names {
+			results[i] = `{"Name":"` + n + `"}`
+		}
+
+		return `{"version":5,"type":"multiinfo","resultcount":0,"results":[` +
+			strings.Join(results, ",") + `]}`
+	}
+
+	testClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		args := req.URL.Query()["arg[]"]
+
+		return len(args) == 2 && args[0] == "a" && args[1] == "b"
+	})).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(chunkPayload("a", "b")))}, nil)
+
+	testClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		args := req.URL.Query()["arg[]"]
+
+		return len(args) == 2 && args[0] == "c" && args[1] == "a"
+	})).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(chunkPayload("c", "a")))}, nil)
+
+	got, err := c.Info(context.Background(), []string{"a", "b", "c", "a"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Pkg{{Name: "a"}, {Name: "b"}, {Name: "c"}}, got)
+	testClient.AssertNumberOfCalls(t, "Do", 2)
+}
+
+func TestClient_Suggest(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c := &Client{
+		BaseURL:        "https://aur.archlinux.org/rpc.php?",
+		HTTPClient:     testClient,
+		RequestEditors: []RequestEditorFn{},
+	}
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`["cower","cower-git"]`))}, nil)
+
+	got, err := c.Suggest(context.Background(), "cow", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cower", "cower-git"}, got)
+
+	testClient.AssertNumberOfCalls(t, "Do", 1)
+
+	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg=cow&type=suggest&v=5",
+		requestMade.URL.String())
+}
+
+func TestClient_SuggestPkgbase(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c := &Client{
+		BaseURL:        "https://aur.archlinux.org/rpc.php?",
+		HTTPClient:     testClient,
+		RequestEditors: []RequestEditorFn{},
+	}
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`["cower"]`))}, nil)
+
+	got, err := c.Suggest(context.Background(), "cow", true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cower"}, got)
+
+	requestMade := testClient.Calls[0].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, "https://aur.archlinux.org/rpc.php?arg=cow&type=suggest-pkgbase&v=5",
+		requestMade.URL.String())
+}
+
+func TestClient_SuggestError(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c := &Client{
+		BaseURL:        "https://aur.archlinux.org/rpc.php?",
+		HTTPClient:     testClient,
+		RequestEditors: []RequestEditorFn{},
+	}
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	_, err := c.Suggest(context.Background(), "cow", false)
+
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+}
+
+func Test_parseSuggestResponse(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`["cower","cower-git"]`)),
+	}
+
+	got, err := parseSuggestResponse(resp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cower", "cower-git"}, got)
+}
+
+func TestClient_InfoMultiError(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(WithHTTPClient(testClient), WithMaxArgsPerRequest(1))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 503,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("{}"))}, nil)
+
+	_, err = c.Info(context.Background(), []string{"a", "b", "c"})
+
+	var multiErr *MultiError
+	assert.ErrorAs(t, err, &multiErr)
+	assert.Len(t, multiErr.Errors, 3)
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+
+	testClient.AssertNumberOfCalls(t, "Do", 3)
+}
+
+// mapCache is a minimal Cache backed by a map, used to exercise Client's
+// caching behavior without depending on a concrete implementation.
+type mapCache struct {
+	entries map[string]*CachedResponse
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{entries: map[string]*CachedResponse{}}
+}
+
+func (m *mapCache) Get(key string) (*CachedResponse, bool) {
+	resp, ok := m.entries[key]
+
+	return resp, ok
+}
+
+func (m *mapCache) Set(key string, resp *CachedResponse, _ time.Duration) {
+	m.entries[key] = resp
+}
+
+func TestClient_CacheHitSkipsRequest(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(WithHTTPClient(testClient), WithCache(newMapCache()))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(validPayload))}, nil).Once()
+
+	got, err := c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, validPayloadItems, got)
+
+	got, err = c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, validPayloadItems, got)
+
+	testClient.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestClient_CacheRevalidatesWithConditionalHeaders(t *testing.T) {
+	testClient := new(MockedClient)
+
+	c, err := NewClient(WithHTTPClient(testClient), WithCache(newMapCache()), WithCacheTTL(time.Nanosecond))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Etag": []string{`"abc"`}, "Last-Modified": []string{"Mon, 01 Jan 2024 00:00:00 GMT"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(validPayload))}, nil).Once()
+
+	_, err = c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusNotModified,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(""))}, nil).Once()
+
+	got, err := c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, validPayloadItems, got)
+
+	testClient.AssertNumberOfCalls(t, "Do", 2)
+
+	revalidation := testClient.Calls[1].Arguments.Get(0).(*http.Request)
+	assert.Equal(t, `"abc"`, revalidation.Header.Get("If-None-Match"))
+	assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", revalidation.Header.Get("If-Modified-Since"))
+}
+
+func Test_cacheKey(t *testing.T) {
+	search := url.Values{}
+	search.Set("type", "search")
+	search.Set("arg", "test")
+
+	info := url.Values{}
+	info.Set("type", "info")
+	info.Add("arg[]", "b")
+	info.Add("arg[]", "a")
+
+	infoReordered := url.Values{}
+	infoReordered.Set("type", "info")
+	infoReordered.Add("arg[]", "a")
+	infoReordered.Add("arg[]", "b")
+
+	assert.NotEqual(t, cacheKey(search), cacheKey(info))
+	assert.Equal(t, cacheKey(info), cacheKey(infoReordered))
+}
+
+func TestClient_ResponseObserverFn_Success(t *testing.T) {
+	testClient := new(MockedClient)
+
+	var gotStatus int
+
+	var gotErr error
+
+	c, err := NewClient(WithHTTPClient(testClient), WithResponseObserverFn(
+		func(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+			gotStatus = resp.StatusCode
+			gotErr = err
+		},
+	))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(validPayload))}, nil)
+
+	_, err = c.Search(context.Background(), "test", Name)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, gotStatus)
+	assert.NoError(t, gotErr)
+}
+
+func TestClient_ResponseObserverFn_TransportError(t *testing.T) {
+	testClient := new(MockedClient)
+
+	var observed int
+
+	wantErr := errors.New("boom")
+
+	c, err := NewClient(WithHTTPClient(testClient), WithResponseObserverFn(
+		func(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+			observed++
+			assert.Nil(t, resp)
+			assert.ErrorIs(t, err, wantErr)
+		},
+	))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return((*http.Response)(nil), wantErr)
+
+	_, err = c.Search(context.Background(), "test", Name)
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, observed)
+}
+
+func TestClient_ResponseObserverFn_SkippedOnCacheHit(t *testing.T) {
+	testClient := new(MockedClient)
+
+	var observed int
+
+	c, err := NewClient(WithHTTPClient(testClient), WithCache(newMapCache()), WithResponseObserverFn(
+		func(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+			observed++
+		},
+	))
+	assert.NoError(t, err)
+
+	testClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(validPayload))}, nil).Once()
+
+	_, err = c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, observed)
+
+	_, err = c.Search(context.Background(), "test", Name)
+	assert.NoError(t, err)
+
+	testClient.AssertNumberOfCalls(t, "Do", 1)
+	assert.Equal(t, 1, observed)
+}
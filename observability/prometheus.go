@@ -0,0 +1,46 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mikkeloscar/aur"
+)
+
+// PrometheusObserver returns an aur.ResponseObserverFn that registers
+// request-count and latency-histogram metrics with reg, labelled by the
+// request's "type" and "by" query parameters and the response status (or
+// "error" when the round-trip itself failed).
+func PrometheusObserver(reg prometheus.Registerer) aur.ResponseObserverFn {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "aur",
+		Subsystem: "client",
+		Name:      "requests_total",
+		Help:      "Total number of AUR RPC requests made by Client.",
+	}, []string{"type", "by", "status"})
+
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "aur",
+		Subsystem: "client",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of AUR RPC requests made by Client.",
+	}, []string{"type", "by", "status"})
+
+	reg.MustRegister(requests, latency)
+
+	return func(ctx context.Context, req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+		typ, by := rpcParams(req)
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+
+		requests.WithLabelValues(typ, by, status).Inc()
+		latency.WithLabelValues(typ, by, status).Observe(elapsed.Seconds())
+	}
+}
@@ -0,0 +1,35 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusObserver_RequestCount(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	observe := PrometheusObserver(reg)
+
+	req := newTestRequest(t, "type=search&by=name")
+	resp := &http.Response{StatusCode: 200}
+
+	observe(context.Background(), req, resp, 5*time.Millisecond, nil)
+	observe(context.Background(), req, nil, time.Millisecond, errors.New("boom"))
+
+	expected := `
+		# HELP aur_client_requests_total Total number of AUR RPC requests made by Client.
+		# TYPE aur_client_requests_total counter
+		aur_client_requests_total{by="name",status="200",type="search"} 1
+		aur_client_requests_total{by="name",status="error",type="search"} 1
+	`
+
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "aur_client_requests_total")
+	assert.NoError(t, err)
+}
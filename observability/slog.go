@@ -0,0 +1,41 @@
+// Package observability provides built-in aur.ResponseObserverFn
+// implementations for monitoring Client's AUR RPC traffic: SlogObserver
+// for structured logging and PrometheusObserver for request-count and
+// latency metrics.
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mikkeloscar/aur"
+)
+
+// SlogObserver returns an aur.ResponseObserverFn that logs one record per
+// AUR RPC round-trip to logger, at Error level on a transport failure and
+// Debug otherwise.
+func SlogObserver(logger *slog.Logger) aur.ResponseObserverFn {
+	return func(ctx context.Context, req *http.Request, resp *http.Response, latency time.Duration, err error) {
+		typ, by := rpcParams(req)
+
+		if err != nil {
+			logger.ErrorContext(ctx, "aur rpc request failed",
+				"type", typ, "by", by, "latency", latency, "error", err)
+
+			return
+		}
+
+		logger.DebugContext(ctx, "aur rpc request",
+			"type", typ, "by", by, "status", resp.StatusCode, "latency", latency)
+	}
+}
+
+// rpcParams extracts the "type" and "by" query parameters from req for use
+// as log fields and metric labels.
+func rpcParams(req *http.Request) (typ, by string) {
+	values := req.URL.Query()
+
+	return values.Get("type"), values.Get("by")
+}
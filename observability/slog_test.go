@@ -0,0 +1,54 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRequest(t *testing.T, rawQuery string) *http.Request {
+	t.Helper()
+
+	return &http.Request{URL: &url.URL{RawQuery: rawQuery}}
+}
+
+func TestSlogObserver_Success(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	observe := SlogObserver(logger)
+
+	req := newTestRequest(t, "type=search&by=name")
+	resp := &http.Response{StatusCode: 200}
+
+	observe(context.Background(), req, resp, 5*time.Millisecond, nil)
+
+	out := buf.String()
+	assert.Contains(t, out, "aur rpc request")
+	assert.Contains(t, out, "type=search")
+	assert.Contains(t, out, "by=name")
+	assert.Contains(t, out, "status=200")
+}
+
+func TestSlogObserver_Error(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	observe := SlogObserver(logger)
+
+	req := newTestRequest(t, "type=info")
+
+	observe(context.Background(), req, nil, time.Millisecond, errors.New("boom"))
+
+	out := buf.String()
+	assert.Contains(t, out, "aur rpc request failed")
+	assert.Contains(t, out, "type=info")
+	assert.Contains(t, out, "error=boom")
+}
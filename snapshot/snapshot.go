@@ -0,0 +1,409 @@
+// Package snapshot downloads AUR package source snapshots referenced by
+// Pkg.URLPath and verifies them against the RPC record they came from.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mikkeloscar/aur"
+)
+
+const defaultBaseURL = "https://aur.archlinux.org"
+
+// ErrMismatch is returned by VerifyAgainstRPC when a snapshot's .SRCINFO
+// disagrees with the RPC record it was fetched for.
+var ErrMismatch = errors.New("snapshot does not match AUR RPC record")
+
+// SnapshotClient downloads and extracts AUR package source snapshots.
+type SnapshotClient struct {
+	// BaseURL snapshots are resolved against, e.g. "https://aur.archlinux.org".
+	BaseURL string
+
+	// Doer for performing requests. Reuses aur.HTTPRequestDoer so the
+	// same transport and auth used for RPC calls apply to snapshot
+	// downloads. SnapshotClient resolves requests against a single
+	// BaseURL and does not retry across mirrors the way aur.Client does.
+	HTTPClient aur.HTTPRequestDoer
+
+	// RequestEditors are applied to every outbound snapshot request.
+	RequestEditors []aur.RequestEditorFn
+}
+
+// SnapshotClientOption allows setting custom parameters during construction.
+type SnapshotClientOption func(*SnapshotClient) error
+
+func NewSnapshotClient(opts ...SnapshotClientOption) (*SnapshotClient, error) {
+	client := SnapshotClient{
+		BaseURL:        defaultBaseURL,
+		HTTPClient:     nil,
+		RequestEditors: []aur.RequestEditorFn{},
+	}
+
+	for _, o := range opts {
+		if err := o(&client); err != nil {
+			return nil, err
+		}
+	}
+
+	if client.HTTPClient == nil {
+		client.HTTPClient = http.DefaultClient
+	}
+
+	if client.BaseURL == "" {
+		client.BaseURL = defaultBaseURL
+	}
+
+	client.BaseURL = strings.TrimSuffix(client.BaseURL, "/")
+
+	return &client, nil
+}
+
+// WithHTTPClient allows overriding the default Doer, which is
+// automatically created using http.Client. This is useful for tests.
+func WithHTTPClient(doer aur.HTTPRequestDoer) SnapshotClientOption {
+	return func(c *SnapshotClient) error {
+		c.HTTPClient = doer
+
+		return nil
+	}
+}
+
+// WithBaseURL overrides the default "https://aur.archlinux.org" host that
+// Pkg.URLPath is resolved against, e.g. to point at a mirror.
+func WithBaseURL(baseURL string) SnapshotClientOption {
+	return func(c *SnapshotClient) error {
+		c.BaseURL = baseURL
+
+		return nil
+	}
+}
+
+// WithRequestEditorFn allows setting up a callback function, which will be
+// called right before sending the request. This can be used to mutate the request.
+func WithRequestEditorFn(fn aur.RequestEditorFn) SnapshotClientOption {
+	return func(c *SnapshotClient) error {
+		c.RequestEditors = append(c.RequestEditors, fn)
+
+		return nil
+	}
+}
+
+func (c *SnapshotClient) applyEditors(ctx context.Context, req *http.Request, additionalEditors []aur.RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Download streams the source tarball referenced by pkg.URLPath into w.
+func (c *SnapshotClient) Download(
+	ctx context.Context, pkg aur.Pkg, w io.Writer, reqEditors ...aur.RequestEditorFn,
+) error {
+	resp, err := c.get(ctx, pkg, reqEditors)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to stream snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// Extract downloads the source tarball referenced by pkg.URLPath,
+// transparently gunzips and untars it, and writes the result under dir,
+// which is created if it doesn't already exist.
+func (c *SnapshotClient) Extract(
+	ctx context.Context, pkg aur.Pkg, dir string, reqEditors ...aur.RequestEditorFn,
+) error {
+	resp, err := c.get(ctx, pkg, reqEditors)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	tr, closeReader, err := tarReader(resp.Body)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	return extractTar(tr, dir)
+}
+
+func extractTar(tr *tar.Reader, dir string) error {
+	cleanDir := filepath.Clean(dir)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		target := filepath.Join(cleanDir, hdr.Name)
+		if target != cleanDir && !strings.HasPrefix(target, cleanDir+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q escapes destination directory", hdr.Name)
+		}
+
+		if err := writeTarEntry(tr, hdr, target); err != nil {
+			return err
+		}
+	}
+}
+
+func writeTarEntry(tr *tar.Reader, hdr *tar.Header, target string) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %q: %w", target, err)
+		}
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(target), err)
+		}
+
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(hdr.Mode))
+		if err != nil {
+			return fmt.Errorf("failed to create file %q: %w", target, err)
+		}
+
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+
+			return fmt.Errorf("failed to write file %q: %w", target, err)
+		}
+
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("failed to close file %q: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// Srcinfo downloads the source tarball referenced by pkg.URLPath and
+// parses its .SRCINFO.
+func (c *SnapshotClient) Srcinfo(
+	ctx context.Context, pkg aur.Pkg, reqEditors ...aur.RequestEditorFn,
+) (*Srcinfo, error) {
+	resp, err := c.get(ctx, pkg, reqEditors)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	tr, closeReader, err := tarReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer closeReader()
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: .SRCINFO not found in snapshot", pkg.Name)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		if filepath.Base(hdr.Name) != ".SRCINFO" {
+			continue
+		}
+
+		return ParseSrcinfo(tr)
+	}
+}
+
+// VerifyAgainstRPC cross-checks the snapshot's .SRCINFO Name, PackageBase,
+// and version against pkg, the RPC record it was fetched for, so callers
+// can detect stale mirrors or a tampered tarball.
+func (c *SnapshotClient) VerifyAgainstRPC(
+	ctx context.Context, pkg aur.Pkg, reqEditors ...aur.RequestEditorFn,
+) error {
+	info, err := c.Srcinfo(ctx, pkg, reqEditors...)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(info.Names, pkg.Name) {
+		return fmt.Errorf("%w: Name %q not among .SRCINFO pkgname entries %v", ErrMismatch, pkg.Name, info.Names)
+	}
+
+	if info.PackageBase != pkg.PackageBase {
+		return fmt.Errorf("%w: PackageBase %q, RPC reports %q", ErrMismatch, info.PackageBase, pkg.PackageBase)
+	}
+
+	version := info.Pkgver
+	if info.Pkgrel != "" {
+		version += "-" + info.Pkgrel
+	}
+
+	if info.Epoch != "" {
+		version = info.Epoch + ":" + version
+	}
+
+	if version != pkg.Version {
+		return fmt.Errorf("%w: Version %q, RPC reports %q", ErrMismatch, version, pkg.Version)
+	}
+
+	return nil
+}
+
+func (c *SnapshotClient) get(
+	ctx context.Context, pkg aur.Pkg, reqEditors []aur.RequestEditorFn,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+pkg.URLPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("unexpected status %d fetching snapshot for %s", resp.StatusCode, pkg.Name)
+	}
+
+	return resp, nil
+}
+
+// tarReader wraps r in a gzip decompressor and tar reader, returning a
+// close function that must be called once the caller is done reading.
+func tarReader(r io.Reader) (*tar.Reader, func(), error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+
+	return tar.NewReader(gr), func() { gr.Close() }, nil
+}
+
+// Srcinfo holds the subset of a package's .SRCINFO fields needed to
+// describe and verify a source snapshot.
+type Srcinfo struct {
+	PackageBase string
+
+	// Names holds every "pkgname" in the .SRCINFO. A split package (one
+	// pkgbase, several pkgname entries) lists more than one; pkg.Name is
+	// expected to be among them.
+	Names []string
+
+	Epoch  string
+	Pkgver string
+	Pkgrel string
+
+	Depends      []string
+	MakeDepends  []string
+	CheckDepends []string
+	OptDepends   []string
+	Source       []string
+
+	// ArchDepends/ArchSource hold architecture-specific entries keyed by
+	// arch, e.g. "depends_x86_64" / "source_x86_64".
+	ArchDepends map[string][]string
+	ArchSource  map[string][]string
+}
+
+// ParseSrcinfo parses the "key = value" pairs of a .SRCINFO file.
+func ParseSrcinfo(r io.Reader) (*Srcinfo, error) {
+	info := &Srcinfo{
+		ArchDepends: map[string][]string{},
+		ArchSource:  map[string][]string{},
+	}
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		applySrcinfoField(info, key, value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read .SRCINFO: %w", err)
+	}
+
+	return info, nil
+}
+
+func applySrcinfoField(info *Srcinfo, key, value string) {
+	switch {
+	case key == "pkgbase":
+		info.PackageBase = value
+	case key == "pkgname":
+		info.Names = append(info.Names, value)
+	case key == "epoch":
+		info.Epoch = value
+	case key == "pkgver":
+		info.Pkgver = value
+	case key == "pkgrel":
+		info.Pkgrel = value
+	case key == "depends":
+		info.Depends = append(info.Depends, value)
+	case key == "makedepends":
+		info.MakeDepends = append(info.MakeDepends, value)
+	case key == "checkdepends":
+		info.CheckDepends = append(info.CheckDepends, value)
+	case key == "optdepends":
+		info.OptDepends = append(info.OptDepends, value)
+	case key == "source":
+		info.Source = append(info.Source, value)
+	case strings.HasPrefix(key, "depends_"):
+		arch := strings.TrimPrefix(key, "depends_")
+		info.ArchDepends[arch] = append(info.ArchDepends[arch], value)
+	case strings.HasPrefix(key, "source_"):
+		arch := strings.TrimPrefix(key, "source_")
+		info.ArchSource[arch] = append(info.ArchSource[arch], value)
+	}
+}
@@ -0,0 +1,321 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mikkeloscar/aur"
+)
+
+const validSrcinfo = `pkgbase = cower
+	pkgdesc = A simple AUR agent with a pretentious name
+	pkgver = 14
+	pkgrel = 2
+	depends = curl
+	depends = openssl
+	makedepends = perl
+	source = cower-14.tar.gz
+	source_x86_64 = cower-14-x86_64.patch
+
+pkgname = cower
+`
+
+// buildSnapshot returns a gzipped tarball containing the given files.
+func buildSnapshot(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0o644,
+			Size: int64(len(content)),
+		}
+
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+
+	return buf.Bytes()
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseSrcinfo(t *testing.T) {
+	info, err := ParseSrcinfo(strings.NewReader(validSrcinfo))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cower", info.PackageBase)
+	assert.Equal(t, []string{"cower"}, info.Names)
+	assert.Equal(t, "", info.Epoch)
+	assert.Equal(t, "14", info.Pkgver)
+	assert.Equal(t, "2", info.Pkgrel)
+	assert.Equal(t, []string{"curl", "openssl"}, info.Depends)
+	assert.Equal(t, []string{"perl"}, info.MakeDepends)
+	assert.Equal(t, []string{"cower-14.tar.gz"}, info.Source)
+	assert.Equal(t, []string{"cower-14-x86_64.patch"}, info.ArchSource["x86_64"])
+}
+
+func TestParseSrcinfo_Epoch(t *testing.T) {
+	const withEpoch = `pkgbase = cower
+	epoch = 1
+	pkgver = 14
+	pkgrel = 2
+
+pkgname = cower
+`
+
+	info, err := ParseSrcinfo(strings.NewReader(withEpoch))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1", info.Epoch)
+}
+
+func TestSnapshotClient_Download(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": validSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "https://aur.archlinux.org/cgit/aur.git/snapshot/cower.tar.gz", req.URL.String())
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{Name: "cower", URLPath: "/cgit/aur.git/snapshot/cower.tar.gz"}
+
+	var out bytes.Buffer
+	err = c.Download(context.Background(), pkg, &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, snapshot, out.Bytes())
+}
+
+func TestSnapshotClient_Extract(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{
+		"cower/.SRCINFO": validSrcinfo,
+		"cower/PKGBUILD": "pkgname=cower\n",
+	})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	pkg := aur.Pkg{Name: "cower", URLPath: "/cgit/aur.git/snapshot/cower.tar.gz"}
+
+	err = c.Extract(context.Background(), pkg, dir)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "cower", "PKGBUILD"))
+	assert.NoError(t, err)
+	assert.Equal(t, "pkgname=cower\n", string(content))
+}
+
+func TestSnapshotClient_Extract_RejectsPathEscape(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"../evil": "pwned"})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	pkg := aur.Pkg{Name: "evil", URLPath: "/cgit/aur.git/snapshot/evil.tar.gz"}
+
+	err = c.Extract(context.Background(), pkg, dir)
+	assert.Error(t, err)
+}
+
+func TestSnapshotClient_Srcinfo(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": validSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{Name: "cower", URLPath: "/cgit/aur.git/snapshot/cower.tar.gz"}
+
+	info, err := c.Srcinfo(context.Background(), pkg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cower", info.PackageBase)
+}
+
+func TestSnapshotClient_VerifyAgainstRPC(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": validSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{
+		Name:        "cower",
+		PackageBase: "cower",
+		Version:     "14-2",
+		URLPath:     "/cgit/aur.git/snapshot/cower.tar.gz",
+	}
+
+	assert.NoError(t, c.VerifyAgainstRPC(context.Background(), pkg))
+}
+
+func TestSnapshotClient_VerifyAgainstRPC_Mismatch(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": validSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{
+		Name:        "cower",
+		PackageBase: "cower",
+		Version:     "15-1",
+		URLPath:     "/cgit/aur.git/snapshot/cower.tar.gz",
+	}
+
+	err = c.VerifyAgainstRPC(context.Background(), pkg)
+	assert.True(t, errors.Is(err, ErrMismatch))
+}
+
+func TestSnapshotClient_VerifyAgainstRPC_SplitPackage(t *testing.T) {
+	const splitSrcinfo = `pkgbase = foo
+	pkgver = 1.0
+	pkgrel = 1
+
+pkgname = foo-lib
+
+pkgname = foo-bin
+`
+
+	snapshot := buildSnapshot(t, map[string]string{"foo/.SRCINFO": splitSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{
+		Name:        "foo-bin",
+		PackageBase: "foo",
+		Version:     "1.0-1",
+		URLPath:     "/cgit/aur.git/snapshot/foo.tar.gz",
+	}
+
+	assert.NoError(t, c.VerifyAgainstRPC(context.Background(), pkg))
+}
+
+func TestSnapshotClient_VerifyAgainstRPC_Epoch(t *testing.T) {
+	const withEpoch = `pkgbase = cower
+	epoch = 1
+	pkgver = 14
+	pkgrel = 2
+
+pkgname = cower
+`
+
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": withEpoch})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{
+		Name:        "cower",
+		PackageBase: "cower",
+		Version:     "1:14-2",
+		URLPath:     "/cgit/aur.git/snapshot/cower.tar.gz",
+	}
+
+	assert.NoError(t, c.VerifyAgainstRPC(context.Background(), pkg))
+}
+
+func TestSnapshotClient_VerifyAgainstRPC_NameMismatch(t *testing.T) {
+	snapshot := buildSnapshot(t, map[string]string{"cower/.SRCINFO": validSrcinfo})
+
+	doer := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       io.NopCloser(bytes.NewReader(snapshot)),
+		}, nil
+	})
+
+	c, err := NewSnapshotClient(WithHTTPClient(doer))
+	assert.NoError(t, err)
+
+	pkg := aur.Pkg{
+		Name:        "cower-git",
+		PackageBase: "cower",
+		Version:     "14-2",
+		URLPath:     "/cgit/aur.git/snapshot/cower.tar.gz",
+	}
+
+	err = c.VerifyAgainstRPC(context.Background(), pkg)
+	assert.True(t, errors.Is(err, ErrMismatch))
+}